@@ -0,0 +1,124 @@
+package msbuild
+
+import (
+  "io"
+  "github.com/kuma777/go-msbuild/xml"
+)
+
+// Project is a template-free vcxproj builder: it constructs the whole
+// <Project xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+// tree in Go, including the standard VCTargetsPath imports, so callers
+// don't need to ship a template.vcxproj next to their executable. This is
+// the recommended API for programmatic use; ExportProject/ExportProjectSpec
+// remain for the template-based workflow.
+type Project struct {
+  root *Element
+
+  projectConfigurations *Element
+  sources                *Element
+  propsImported          bool
+}
+
+// NewProject creates an empty project with the given ToolsVersion (e.g.
+// "4.0") and DefaultTargets (e.g. "Build"), and the Microsoft.Cpp.Default.props
+// import already in place.
+func NewProject(toolsVersion, defaultTargets string) *Project {
+  root := &Element{}
+  root.name.Local = "Project"
+  root.name.Space = "http://schemas.microsoft.com/developer/msbuild/2003"
+  root.AddAttribute("DefaultTargets", defaultTargets)
+  root.AddAttribute("ToolsVersion", toolsVersion)
+  root.AddAttribute("xmlns", root.name.Space)
+
+  p := &Project{root: root}
+
+  p.projectConfigurations = root.AddChild("ItemGroup")
+  p.projectConfigurations.AddAttribute("Label", "ProjectConfigurations")
+
+  p.addImport(`$(VCTargetsPath)\Microsoft.Cpp.Default.props`)
+
+  return p
+}
+
+func (p *Project) addImport(project string) *Element {
+  imp := p.root.AddChild("Import")
+  imp.AddAttribute("Project", project)
+  return imp
+}
+
+// ensureProps inserts the Microsoft.Cpp.props import the first time it's
+// needed. Real vcxprojs place it right after the Configuration
+// PropertyGroups and before any ItemDefinitionGroup/ItemGroup content, so
+// it's added lazily on first use of AddClCompile/AddItemDefinitionGroup
+// (or by Write, for a project with no sources or ItemDefinitionGroups at
+// all) rather than up front in NewProject, before AddConfiguration has had
+// a chance to add its PropertyGroups.
+func (p *Project) ensureProps() {
+  if p.propsImported {
+    return
+  }
+  p.propsImported = true
+  p.addImport(`$(VCTargetsPath)\Microsoft.Cpp.props`)
+}
+
+// AddConfiguration declares a Configuration|Platform pair: an entry in the
+// ProjectConfigurations ItemGroup (keyed by its Include attribute) plus its
+// matching Configuration PropertyGroup, conditioned the way Visual Studio
+// generates it.
+// It returns the PropertyGroup so the caller can populate it with the
+// properties that belong there (ConfigurationType, PlatformToolset,
+// UseDebugLibraries, CharacterSet, etc.).
+func (p *Project) AddConfiguration(name, platform string) *Element {
+  cfg := Config{Name: name, Platform: platform}
+
+  entry := p.projectConfigurations.AddChild("ProjectConfiguration")
+  entry.AddAttribute("Include", cfg.String())
+  confChild := entry.AddChild("Configuration")
+  confChild.AddCharData(name)
+  platChild := entry.AddChild("Platform")
+  platChild.AddCharData(platform)
+
+  group := p.root.AddChild("PropertyGroup")
+  group.AddAttribute("Label", "Configuration")
+  group.AddAttribute("Condition", "'$(Configuration)|$(Platform)'=='" + cfg.String() + "'")
+  return group
+}
+
+// AddClCompile adds a source file to the project's Sources ItemGroup,
+// creating that ItemGroup on first use, and returns the ClCompile element
+// so the caller can add further metadata (e.g. per-config excludes).
+func (p *Project) AddClCompile(file string) *Element {
+  p.ensureProps()
+
+  if p.sources == nil {
+    p.sources = p.root.AddChild("ItemGroup")
+    p.sources.AddAttribute("Label", "Sources")
+  }
+
+  child := p.sources.AddChild("ClCompile")
+  child.AddAttribute("Include", file)
+  return child
+}
+
+// AddItemDefinitionGroup adds an ItemDefinitionGroup (the element that
+// carries per-configuration compiler/linker settings), conditioned on the
+// given Configuration|Platform condition, and returns it so the caller can
+// populate it with AddChild/AddAttribute.
+func (p *Project) AddItemDefinitionGroup(condition string) *Element {
+  p.ensureProps()
+
+  group := p.root.AddChild("ItemDefinitionGroup")
+  if condition != "" {
+    group.AddAttribute("Condition", condition)
+  }
+  return group
+}
+
+// Write finalizes the standard Microsoft.Cpp.props/.targets imports and
+// encodes the project as XML to w.
+func (p *Project) Write(w io.Writer) error {
+  p.ensureProps()
+  p.addImport(`$(VCTargetsPath)\Microsoft.Cpp.targets`)
+
+  return xml.NewEncoder(w).Encode(p.root)
+}
@@ -0,0 +1,55 @@
+package msbuild
+
+import (
+  "bytes"
+  "strings"
+  "testing"
+)
+
+func TestProjectBuilderImportOrderingAndConfiguration(t *testing.T) {
+  p := NewProject("17.0", "Build")
+
+  cfg := p.AddConfiguration("Debug", "Win32")
+  cfg.AddChild("ConfigurationType").AddCharData("Application")
+  cfg.AddChild("PlatformToolset").AddCharData("v143")
+
+  p.AddItemDefinitionGroup("'$(Configuration)|$(Platform)'=='Debug|Win32'")
+  p.AddClCompile("main.cpp")
+
+  var buf bytes.Buffer
+  if err := p.Write(&buf); err != nil {
+    t.Fatalf("Write: %v", err)
+  }
+  out := buf.String()
+
+  idxDefaultProps := strings.Index(out, "Microsoft.Cpp.Default.props")
+  idxConfigType := strings.Index(out, "ConfigurationType")
+  idxProps := strings.Index(out, "Microsoft.Cpp.props")
+  idxItemDefGroup := strings.Index(out, "ItemDefinitionGroup")
+  idxTargets := strings.Index(out, "Microsoft.Cpp.targets")
+
+  for _, v := range []int{idxDefaultProps, idxConfigType, idxProps, idxItemDefGroup, idxTargets} {
+    if v < 0 {
+      t.Fatalf("expected output to contain all of Default.props/ConfigurationType/.props/ItemDefinitionGroup/.targets, got:\n%s", out)
+    }
+  }
+
+  if !(idxDefaultProps < idxConfigType && idxConfigType < idxProps && idxProps < idxItemDefGroup && idxItemDefGroup < idxTargets) {
+    t.Errorf("unexpected element ordering, want Default.props < ConfigurationType < Microsoft.Cpp.props < ItemDefinitionGroup < Microsoft.Cpp.targets, got:\n%s", out)
+  }
+}
+
+func TestProjectBuilderWriteWithNoSources(t *testing.T) {
+  p := NewProject("17.0", "Build")
+  p.AddConfiguration("Debug", "Win32")
+
+  var buf bytes.Buffer
+  if err := p.Write(&buf); err != nil {
+    t.Fatalf("Write: %v", err)
+  }
+
+  out := buf.String()
+  if !strings.Contains(out, "Microsoft.Cpp.props") || !strings.Contains(out, "Microsoft.Cpp.targets") {
+    t.Errorf("expected both props and targets imports even with no sources/ItemDefinitionGroups, got:\n%s", out)
+  }
+}
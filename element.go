@@ -0,0 +1,267 @@
+package msbuild
+
+import (
+  "io"
+  "github.com/kuma777/go-msbuild/xml"
+)
+
+// Token is anything that can appear as a child of an Element: another
+// Element, or a raw XML token (character data, a comment, a processing
+// instruction or a directive). Keeping these in a single ordered
+// children slice lets Element round-trip a document's content in its
+// original order, instead of only preserving the StartElement/CharData it
+// happened to care about.
+type Token interface {
+  isToken()
+}
+
+// CharData is literal text between tags.
+type CharData string
+
+func (CharData) isToken() {}
+
+// Comment is a <!-- ... --> comment.
+type Comment string
+
+func (Comment) isToken() {}
+
+// Directive is a <!DOCTYPE ...> or similar directive.
+type Directive string
+
+func (Directive) isToken() {}
+
+// ProcInst is a <?target inst?> processing instruction, e.g. the
+// <?xml version="1.0" encoding="utf-8"?> header at the top of a vcxproj.
+type ProcInst struct {
+  Target string
+  Inst   string
+}
+
+func (ProcInst) isToken() {}
+
+type TemplateCallback func(element *Element)
+
+// Element is a generic XML element. Its children are kept as an ordered
+// []Token rather than split across separate fields, so that comments,
+// processing instructions and directives interleaved with child elements
+// round-trip through Encode/Decode in their original positions.
+type Element struct {
+  name        xml.Name
+  attributes  []xml.Attr
+  children    []Token
+}
+
+func (*Element) isToken() {}
+
+func (e *Element) AddAttribute(name, value string) {
+  var attr xml.Attr
+  attr.Name.Local = name
+  attr.Value = value
+  e.attributes = append(e.attributes, attr)
+}
+
+// Attribute returns the value of the attribute named name, and whether it
+// was present at all. Callers that previously assumed a specific
+// attribute existed (e.g. "the first attribute is always Include") should
+// use this instead, since hand-authored XML can't be relied on to follow
+// that convention.
+func (e *Element) Attribute(name string) (string, bool) {
+  for _, attr := range e.attributes {
+    if attr.Name.Local == name {
+      return attr.Value, true
+    }
+  }
+  return "", false
+}
+
+func (e *Element) AddChild(name string) *Element {
+  child := &Element{}
+  child.name.Local = name
+  e.children = append(e.children, child)
+  return child
+}
+
+func (e *Element) AddCharData(value string) {
+  e.children = append(e.children, CharData(value))
+}
+
+func (in *Element) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+  // The decoder resolves a namespace into in.name.Space but also keeps the
+  // xmlns/xmlns:* declaration that produced it as a literal attribute in
+  // in.attributes. Re-emitting in.name.Space here as well would duplicate
+  // that attribute on the declaring element and spray a redundant xmlns
+  // onto every element that merely inherits it.
+  start.Name = xml.Name{Local: in.name.Local}
+  start.Attr = in.attributes
+  e.EncodeToken(start)
+  for _, child := range in.children {
+    switch c := child.(type) {
+    case *Element:
+      if err := e.Encode(c); err != nil {
+        return err
+      }
+    case CharData:
+      if err := e.EncodeToken(xml.CharData(c)); err != nil {
+        return err
+      }
+    case Comment:
+      if err := e.EncodeToken(xml.Comment(c)); err != nil {
+        return err
+      }
+    case Directive:
+      if err := e.EncodeToken(xml.Directive(c)); err != nil {
+        return err
+      }
+    case ProcInst:
+      if err := e.EncodeToken(xml.ProcInst{Target: c.Target, Inst: []byte(c.Inst)}); err != nil {
+        return err
+      }
+    }
+  }
+  e.EncodeToken(start.End())
+  return nil
+}
+
+func (out *Element) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+  out.name        = start.Name
+  out.attributes  = start.Attr
+
+  for {
+    token, err := d.Token()
+    if err != nil {
+      if err == io.EOF {
+        return nil
+      }
+      return err
+    }
+
+    switch t := token.(type) {
+    case xml.StartElement:
+      var element *Element
+      if err := d.DecodeElement(&element, &t); err != nil {
+        return err
+      }
+      out.children = append(out.children, element)
+    case xml.CharData:
+      out.children = append(out.children, CharData(t.Copy()))
+    case xml.Comment:
+      out.children = append(out.children, Comment(t.Copy()))
+    case xml.Directive:
+      out.children = append(out.children, Directive(t.Copy()))
+    case xml.ProcInst:
+      out.children = append(out.children, ProcInst{Target: t.Target, Inst: string(t.Inst)})
+    }
+  }
+}
+
+// Document represents a full XML document: the root Element plus any
+// comments, processing instructions or directives that appear before or
+// after it, most commonly the <?xml version="1.0" ...?> declaration above
+// <Project> in a vcxproj template. Decoding straight into an *Element (as
+// ExportProject used to) discards those document-level tokens, since the
+// decoder only ever hands UnmarshalXML the root element's own children.
+type Document struct {
+  Prologue []Token
+  Root     *Element
+  Epilogue []Token
+}
+
+// DecodeDocument reads a Document from r, preserving any comments,
+// processing instructions and directives before and after the root
+// element.
+func DecodeDocument(r io.Reader) (*Document, error) {
+  d := xml.NewDecoder(r)
+  doc := &Document{}
+
+  for doc.Root == nil {
+    token, err := d.Token()
+    if err != nil {
+      return nil, err
+    }
+
+    switch t := token.(type) {
+    case xml.StartElement:
+      var root *Element
+      if err := d.DecodeElement(&root, &t); err != nil {
+        return nil, err
+      }
+      doc.Root = root
+    case xml.ProcInst:
+      doc.Prologue = append(doc.Prologue, ProcInst{Target: t.Target, Inst: string(t.Inst)})
+    case xml.Comment:
+      doc.Prologue = append(doc.Prologue, Comment(t.Copy()))
+    case xml.Directive:
+      doc.Prologue = append(doc.Prologue, Directive(t.Copy()))
+    }
+  }
+
+  for {
+    token, err := d.Token()
+    if err != nil {
+      if err == io.EOF {
+        return doc, nil
+      }
+      return nil, err
+    }
+
+    switch t := token.(type) {
+    case xml.ProcInst:
+      doc.Epilogue = append(doc.Epilogue, ProcInst{Target: t.Target, Inst: string(t.Inst)})
+    case xml.Comment:
+      doc.Epilogue = append(doc.Epilogue, Comment(t.Copy()))
+    case xml.Directive:
+      doc.Epilogue = append(doc.Epilogue, Directive(t.Copy()))
+    }
+  }
+}
+
+// Encode writes the document back out, in the same prologue/root/epilogue
+// order it was decoded in.
+func (doc *Document) Encode(w io.Writer) error {
+  enc := xml.NewEncoder(w)
+  enc.Indent("", "  ")
+
+  for _, tok := range doc.Prologue {
+    if err := encodeToken(enc, tok); err != nil {
+      return err
+    }
+  }
+
+  if err := enc.Encode(doc.Root); err != nil {
+    return err
+  }
+
+  for _, tok := range doc.Epilogue {
+    if err := encodeToken(enc, tok); err != nil {
+      return err
+    }
+  }
+
+  return enc.Flush()
+}
+
+func encodeToken(enc *xml.Encoder, tok Token) error {
+  switch t := tok.(type) {
+  case ProcInst:
+    return enc.EncodeToken(xml.ProcInst{Target: t.Target, Inst: []byte(t.Inst)})
+  case Comment:
+    return enc.EncodeToken(xml.Comment(t))
+  case Directive:
+    return enc.EncodeToken(xml.Directive(t))
+  }
+  return nil
+}
+
+// scanTemplate calls callback for every ItemGroup element in the tree,
+// at any depth, including ItemGroups nested inside other ItemGroups.
+func scanTemplate(element *Element, callback TemplateCallback) {
+  if element.name.Local == "ItemGroup" {
+    callback(element)
+  }
+
+  for _, child := range element.children {
+    if c, ok := child.(*Element); ok {
+      scanTemplate(c, callback)
+    }
+  }
+}
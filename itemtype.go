@@ -0,0 +1,70 @@
+package msbuild
+
+import (
+  "path/filepath"
+  "strings"
+)
+
+// itemTypeEntry pairs the MSBuild item name (e.g. "ClCompile") used in the
+// project's ItemGroups with the Label of the ItemGroup it belongs to (e.g.
+// "Sources"), so both overrideSources-style template substitution and
+// exportFilter can share the same registry.
+type itemTypeEntry struct {
+  itemName string
+  label    string
+}
+
+// itemTypeRegistry maps a lower-cased file extension (including the dot) to
+// the MSBuild item type it should be emitted as.
+var itemTypeRegistry = map[string]itemTypeEntry{
+  ".cpp":   {"ClCompile", "Sources"},
+  ".cxx":   {"ClCompile", "Sources"},
+  ".c":     {"ClCompile", "Sources"},
+  ".cc":    {"ClCompile", "Sources"},
+  ".asm":   {"MASM", "Masm"},
+  ".s":     {"MASM", "Masm"},
+  ".rc":    {"ResourceCompile", "Resources"},
+  ".idl":   {"Midl", "Midl"},
+  ".natvis": {"Natvis", "Natvis"},
+  ".h":     {"ClInclude", "Headers"},
+  ".hpp":   {"ClInclude", "Headers"},
+  ".hxx":   {"ClInclude", "Headers"},
+  ".inl":   {"ClInclude", "Headers"},
+}
+
+// RegisterItemType associates a file extension (with or without a leading
+// dot) with the MSBuild item name used to include files of that type, and
+// the Label of the ItemGroup they should be written into (e.g. "Sources",
+// "Headers", "Resources"). Registering an extension that already exists
+// overrides the previous entry.
+func RegisterItemType(ext, itemName, label string) {
+  ext = strings.ToLower(ext)
+  if !strings.HasPrefix(ext, ".") {
+    ext = "." + ext
+  }
+
+  itemTypeRegistry[ext] = itemTypeEntry{itemName: itemName, label: label}
+}
+
+// itemTypeFor looks up the registered item type for a file's extension. The
+// second return value is false when the extension is not registered.
+func itemTypeFor(file string) (itemTypeEntry, bool) {
+  entry, ok := itemTypeRegistry[strings.ToLower(filepath.Ext(file))]
+  return entry, ok
+}
+
+// registeredLabels returns the set of ItemGroup Labels that some
+// registered item type writes into (the six built-ins, plus whatever
+// RegisterItemType has added since). ExportProject/ExportProjectSpec use
+// this to tell which template ItemGroups are theirs to fill with files
+// (appended after any items the group already holds) — an ItemGroup
+// labelled e.g.
+// "ProjectConfigurations" must never match, since nothing registers files
+// under that label.
+func registeredLabels() map[string]bool {
+  labels := make(map[string]bool, len(itemTypeRegistry))
+  for _, entry := range itemTypeRegistry {
+    labels[entry.label] = true
+  }
+  return labels
+}
@@ -0,0 +1,74 @@
+package msbuild
+
+import "testing"
+
+func TestItemTypeForBuiltins(t *testing.T) {
+  cases := map[string]itemTypeEntry{
+    "foo.cpp": {"ClCompile", "Sources"},
+    "foo.H":   {"ClInclude", "Headers"},
+    "foo.rc":  {"ResourceCompile", "Resources"},
+  }
+
+  for file, want := range cases {
+    got, ok := itemTypeFor(file)
+    if !ok {
+      t.Errorf("itemTypeFor(%q) not found, want %v", file, want)
+      continue
+    }
+    if got != want {
+      t.Errorf("itemTypeFor(%q) = %v, want %v", file, got, want)
+    }
+  }
+}
+
+func TestItemTypeForUnknownExtension(t *testing.T) {
+  if _, ok := itemTypeFor("foo.xyz"); ok {
+    t.Errorf("itemTypeFor(%q) should not be registered by default", "foo.xyz")
+  }
+}
+
+func TestRegisterItemType(t *testing.T) {
+  originalH, hadH := itemTypeRegistry[".h"]
+  t.Cleanup(func() {
+    delete(itemTypeRegistry, ".xyz")
+    if hadH {
+      itemTypeRegistry[".h"] = originalH
+    } else {
+      delete(itemTypeRegistry, ".h")
+    }
+  })
+
+  RegisterItemType("xyz", "CustomBuild", "Custom")
+
+  got, ok := itemTypeFor("foo.xyz")
+  if !ok {
+    t.Fatalf("itemTypeFor(%q) not found after RegisterItemType", "foo.xyz")
+  }
+  want := itemTypeEntry{"CustomBuild", "Custom"}
+  if got != want {
+    t.Errorf("itemTypeFor(%q) = %v, want %v", "foo.xyz", got, want)
+  }
+
+  // Registering an extension that is already registered should override it.
+  RegisterItemType(".h", "CustomHeader", "CustomHeaders")
+  got, ok = itemTypeFor("foo.h")
+  if !ok || got.itemName != "CustomHeader" {
+    t.Errorf("RegisterItemType should override existing entries, got %v, ok=%v", got, ok)
+  }
+}
+
+func TestRegisteredLabels(t *testing.T) {
+  t.Cleanup(func() { delete(itemTypeRegistry, ".zzz") })
+  RegisterItemType(".zzz", "CustomBuild", "Exotic")
+
+  labels := registeredLabels()
+  for _, want := range []string{"Sources", "Headers", "Resources", "Masm", "Midl", "Natvis", "Exotic"} {
+    if !labels[want] {
+      t.Errorf("registeredLabels() missing %q", want)
+    }
+  }
+
+  if labels["ProjectConfigurations"] {
+    t.Error("registeredLabels() should not include labels nothing is registered under, e.g. ProjectConfigurations")
+  }
+}
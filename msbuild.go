@@ -2,7 +2,6 @@ package msbuild
 
 import (
   "fmt"
-  "io"
   "os"
   "path/filepath"
   "github.com/google/uuid"
@@ -13,129 +12,20 @@ var (
   UUIDSPACE string = "10758f2f-f8bc-4d6b-aeaa-8131bf78a862" // Your UUID Space Here
 )
 
-type TemplateCallback func(element *Element)
-
-type Element struct {
-  name        xml.Name
-  attributes  []xml.Attr
-  children    []interface{}
-}
-
-func (e *Element) AddAttribute(name, value string) {
-  var attr xml.Attr
-  attr.Name.Local = name
-  attr.Value = value
-  e.attributes = append(e.attributes, attr)
-}
-
-func (e *Element) AddChild(name string) *Element {
-  child := &Element{}
-  child.name.Local = name
-  e.children = append(e.children, child)
-  return child
-}
-
-func (e *Element) AddCharData(value string) {
-  child := xml.CharData(value)
-  e.children = append(e.children, child)
-}
-
-func (in *Element) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-  start.Name = in.name
-  start.Attr = in.attributes
-  e.EncodeToken(start)
-  for _, child := range in.children {
-    switch child.(type) {
-    case *Element:
-      c := child.(*Element)
-      err := e.Encode(c)
-      if err != nil {
-        return err
-      }
-    case xml.CharData:
-      e.EncodeToken(child.(xml.CharData))
-    case xml.Comment:
-      e.EncodeToken(child.(xml.Comment))
-    }
-  }
-  e.EncodeToken(start.End())
-  return nil
-}
-
-func (out *Element) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-  out.name        = start.Name
-  out.name.Space  = ""
-  out.attributes  = start.Attr
-
-  for {
-    token, err := d.Token()
-    if err != nil {
-      if err == io.EOF {
-        return nil
-      }
-      return err
-    }
-
-    switch token.(type) {
-    case xml.StartElement:
-      var element *Element
-      t := token.(xml.StartElement)
-      err := d.DecodeElement(&element, &t)
-      if err != nil {
-        return err
-      }
-      out.children = append(out.children, element)
-    case xml.CharData:
-      out.children = append(out.children, token.(xml.CharData).Copy())
-    case xml.Comment:
-      out.children = append(out.children, token.(xml.Comment).Copy())
-    }
-  }
-}
-
-func scanTemplate(element *Element, callback TemplateCallback) {
-  if element.name.Local == "ItemGroup" {
-    callback(element)
-  } else {
-    for _, child := range element.children {
-      switch child.(type) {
-      case *Element:
-        scanTemplate(child.(*Element), callback)
-      default:
-        // NO-OP
-      }
-    }
-  }
-}
-
-func overrideSources(element *Element, files []string) {
-  element.attributes = element.attributes[:0]
-
-  for _, file := range files {
-    file = filepath.FromSlash(file)
-    ext := filepath.Ext(file)
-    if ext != ".cpp" && ext != ".cxx" {
-      continue
-    }
-
-    child := element.AddChild("ClCompile")
-    child.AddAttribute("Include", file)
-
-    element.AddCharData("\n")
-  }
-}
-
-func overrideHeaders(element *Element, files []string) {
+// overrideItems fills an ItemGroup labelled for the given label (e.g.
+// "Sources", "Headers", "Resources") with the files whose registered item
+// type belongs to that label.
+func overrideItems(element *Element, files []string, label string) {
   element.attributes = element.attributes[:0]
 
   for _, file := range files {
     file = filepath.FromSlash(file)
-    ext := filepath.Ext(file)
-    if ext != ".h" {
+    entry, ok := itemTypeFor(file)
+    if !ok || entry.label != label {
       continue
     }
 
-    child := element.AddChild("ClInclude")
+    child := element.AddChild(entry.itemName)
     child.AddAttribute("Include", file)
 
     element.AddCharData("\n")
@@ -155,20 +45,20 @@ func ExportProject(files []string, outdir, projname string) {
     return
   }
 
-  element := &Element{}
-  xml.NewDecoder(fp_in).Decode(&element)
-
+  doc, err := DecodeDocument(fp_in)
   fp_in.Close()
+  if err != nil {
+    fmt.Println("An error occurred while parsing the project template.")
+    return
+  }
 
+  element := doc.Root
+
+  labels := registeredLabels()
   fn := func(element *Element) {
     for _, attr := range element.attributes {
-      if attr.Name.Local == "Label" {
-        switch attr.Value {
-        case "Sources":
-          overrideSources(element, files)
-        case "Headers":
-          overrideHeaders(element, files)
-        }
+      if attr.Name.Local == "Label" && labels[attr.Value] {
+        overrideItems(element, files, attr.Value)
       }
     }
   }
@@ -182,7 +72,7 @@ func ExportProject(files []string, outdir, projname string) {
     fmt.Println("File opening error occurred while writing project file.")
     return
   }
-  xml.NewEncoder(fp_out).Encode(element)
+  doc.Encode(fp_out)
 
   fp_out.Close()
 
@@ -216,18 +106,22 @@ func exportFilter(files []string, outpath string) {
       continue
     }
 
-    ext := filepath.Ext(file)
+    entry, ok := itemTypeFor(file)
+    if !ok {
+      continue
+    }
+
     name := ""
-    tag := ""
-    if ext == ".h" {
+    switch entry.label {
+    case "Headers":
       name = filepath.Join("Header Files", dir)
-      tag = "ClInclude"
-    } else {
+    case "Resources":
+      name = filepath.Join("Resource Files", dir)
+    default:
       name = filepath.Join("Source Files", dir)
-      tag = "ClCompile"
     }
 
-    child := elmEntries.AddChild(tag)
+    child := elmEntries.AddChild(entry.itemName)
     child.AddAttribute("Include", file)
     filter := child.AddChild("Filter")
     filter.AddCharData(name)
@@ -236,8 +130,12 @@ func exportFilter(files []string, outpath string) {
     for {
       filterExists := false
       for _, elm := range elmFilters.children {
-        value := elm.(*Element).attributes[0].Value
-        if path == value {
+        child, ok := elm.(*Element)
+        if !ok {
+          continue
+        }
+        value, ok := child.Attribute("Include")
+        if ok && path == value {
           filterExists = true
           break
         }
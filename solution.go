@@ -0,0 +1,100 @@
+package msbuild
+
+import (
+  "fmt"
+  "os"
+  "path/filepath"
+  "strings"
+  "github.com/google/uuid"
+)
+
+// vcxprojTypeGUID is the well-known MSBuild project type GUID that Visual
+// Studio uses to identify a Visual C++ project inside a .sln file.
+const vcxprojTypeGUID = "8BC9CEB8-8B4A-11D0-8D11-00A0C91BC942"
+
+// solutionConfigs lists the Configuration|Platform pairs written into the
+// SolutionConfigurationPlatforms / ProjectConfigurationPlatforms sections.
+var solutionConfigs = []string{
+  "Debug|Win32",
+  "Debug|x64",
+  "Release|Win32",
+  "Release|x64",
+}
+
+// SolutionProject describes a single vcxproj to be referenced from a
+// generated .sln file.
+type SolutionProject struct {
+  Name string
+  Path string // path to the .vcxproj, relative to the .sln
+}
+
+func guidFor(seed string) string {
+  space, err := uuid.Parse(UUIDSPACE)
+  if err != nil {
+    return "{00000000-0000-0000-0000-000000000000}"
+  }
+
+  return "{" + strings.ToUpper(uuid.NewSHA1(space, []byte(seed)).String()) + "}"
+}
+
+// ExportSolution writes a Visual Studio .sln file referencing the given
+// projects, alongside proper solution/project GUIDs and a
+// SolutionConfigurationPlatforms section for Debug/Release x Win32/x64.
+func ExportSolution(projects []SolutionProject, outdir, slnname string) {
+  solutionGUID := guidFor(slnname)
+
+  projectGUIDs := make([]string, len(projects))
+  for i, project := range projects {
+    projectGUIDs[i] = guidFor(slnname + "/" + project.Name)
+  }
+
+  var sb strings.Builder
+
+  sb.WriteString("\n")
+  sb.WriteString("Microsoft Visual Studio Solution File, Format Version 12.00\n")
+  sb.WriteString("# Visual Studio Version 17\n")
+  sb.WriteString("VisualStudioVersion = 17.0.31903.59\n")
+  sb.WriteString("MinimumVisualStudioVersion = 10.0.40219.1\n")
+
+  for i, project := range projects {
+    path := filepath.ToSlash(project.Path)
+    fmt.Fprintf(&sb, "Project(\"{%s}\") = \"%s\", \"%s\", \"%s\"\n", vcxprojTypeGUID, project.Name, path, projectGUIDs[i])
+    sb.WriteString("EndProject\n")
+  }
+
+  sb.WriteString("Global\n")
+
+  sb.WriteString("\tGlobalSection(SolutionConfigurationPlatforms) = preSolution\n")
+  for _, cfg := range solutionConfigs {
+    fmt.Fprintf(&sb, "\t\t%s = %s\n", cfg, cfg)
+  }
+  sb.WriteString("\tEndGlobalSection\n")
+
+  sb.WriteString("\tGlobalSection(ProjectConfigurationPlatforms) = postSolution\n")
+  for _, guid := range projectGUIDs {
+    for _, cfg := range solutionConfigs {
+      fmt.Fprintf(&sb, "\t\t%s.%s.ActiveCfg = %s\n", guid, cfg, cfg)
+      fmt.Fprintf(&sb, "\t\t%s.%s.Build.0 = %s\n", guid, cfg, cfg)
+    }
+  }
+  sb.WriteString("\tEndGlobalSection\n")
+
+  sb.WriteString("\tGlobalSection(SolutionProperties) = preSolution\n")
+  sb.WriteString("\t\tHideSolutionNode = FALSE\n")
+  sb.WriteString("\tEndGlobalSection\n")
+
+  fmt.Fprintf(&sb, "\tGlobalSection(ExtensibilityGlobals) = postSolution\n\t\tSolutionGuid = %s\n\tEndGlobalSection\n", solutionGUID)
+
+  sb.WriteString("EndGlobal\n")
+
+  outpath := filepath.Join(filepath.ToSlash(outdir), slnname + ".sln")
+
+  fp_out, err := os.OpenFile(outpath, os.O_CREATE | os.O_TRUNC | os.O_WRONLY, 0666)
+  if err != nil {
+    fmt.Println("File opening error occurred while writing solution file.")
+    return
+  }
+  defer fp_out.Close()
+
+  fp_out.WriteString(sb.String())
+}
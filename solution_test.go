@@ -0,0 +1,55 @@
+package msbuild
+
+import (
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+func TestExportSolution(t *testing.T) {
+  outdir := t.TempDir()
+
+  projects := []SolutionProject{
+    {Name: "Foo", Path: "Foo/Foo.vcxproj"},
+    {Name: "Bar", Path: "Bar/Bar.vcxproj"},
+  }
+
+  ExportSolution(projects, outdir, "MySolution")
+
+  out, err := os.ReadFile(filepath.Join(outdir, "MySolution.sln"))
+  if err != nil {
+    t.Fatalf("reading generated .sln: %v", err)
+  }
+  sln := string(out)
+
+  for _, want := range []string{
+    "Microsoft Visual Studio Solution File, Format Version 12.00",
+    `"Foo", "Foo/Foo.vcxproj"`,
+    `"Bar", "Bar/Bar.vcxproj"`,
+    "GlobalSection(SolutionConfigurationPlatforms) = preSolution",
+    "Debug|Win32 = Debug|Win32",
+    "Release|x64 = Release|x64",
+    "GlobalSection(ProjectConfigurationPlatforms) = postSolution",
+    "GlobalSection(ExtensibilityGlobals) = postSolution",
+    "SolutionGuid = " + guidFor("MySolution"),
+    "EndGlobal",
+  } {
+    if !strings.Contains(sln, want) {
+      t.Errorf("generated .sln missing %q:\n%s", want, sln)
+    }
+  }
+}
+
+func TestGuidForIsDeterministicAndUnique(t *testing.T) {
+  a1 := guidFor("MySolution/Foo")
+  a2 := guidFor("MySolution/Foo")
+  if a1 != a2 {
+    t.Errorf("guidFor should be deterministic for the same seed: %q != %q", a1, a2)
+  }
+
+  b := guidFor("MySolution/Bar")
+  if a1 == b {
+    t.Errorf("guidFor should produce different GUIDs for different seeds, got %q for both", a1)
+  }
+}
@@ -0,0 +1,256 @@
+package msbuild
+
+import (
+  "fmt"
+  "os"
+  "path/filepath"
+  "strings"
+)
+
+// Config identifies a single MSBuild configuration/platform pair, e.g.
+// {"Debug", "Win32"}.
+type Config struct {
+  Name     string
+  Platform string
+}
+
+func (c Config) String() string {
+  return c.Name + "|" + c.Platform
+}
+
+// ConfigSpec carries the per-configuration settings ExportProjectSpec
+// writes into the matching PropertyGroup/ItemDefinitionGroup in the
+// template.
+type ConfigSpec struct {
+  Defines     []string
+  IncludeDirs []string
+  LibDirs     []string
+  Libs        []string
+
+  Optimization     string
+  LanguageStandard string
+  RuntimeLibrary   string
+
+  OutDir string
+  IntDir string
+}
+
+// FileSpec carries per-file overrides that apply regardless of, or in
+// addition to, the per-configuration settings above.
+type FileSpec struct {
+  ExcludedConfigs    []Config // configurations this file is excluded from
+  ForcePrecompiledHeader bool // force this file to create the PCH
+}
+
+// ProjectSpec is a typed description of everything ExportProject otherwise
+// leaves to the on-disk template: per-configuration defines, include/lib
+// directories, output directories and language settings, plus per-file
+// overrides.
+type ProjectSpec struct {
+  Configs map[Config]ConfigSpec
+  Files   map[string]FileSpec
+}
+
+// ExportProjectSpec behaves like ExportProject, but additionally rewrites
+// PropertyGroup and ItemDefinitionGroup elements whose
+// Condition="'$(Configuration)|$(Platform)'=='...'" matches a Config in
+// spec.Configs, overwriting their child elements from the corresponding
+// ConfigSpec, and applies any per-file overrides from spec.Files.
+func ExportProjectSpec(files []string, outdir, projname string, spec ProjectSpec) {
+  exepath, err := filepath.Abs(filepath.Dir(os.Args[0]))
+  if err != nil {
+    fmt.Println("An error occurred while getting executable path.")
+    return
+  }
+
+  fp_in, err := os.OpenFile(filepath.Join(exepath, "template.vcxproj"), os.O_RDONLY, 0666)
+  if err != nil {
+    fmt.Println("File opening error occurred while reading project template.")
+    return
+  }
+
+  doc, err := DecodeDocument(fp_in)
+  fp_in.Close()
+  if err != nil {
+    fmt.Println("An error occurred while parsing the project template.")
+    return
+  }
+
+  element := doc.Root
+
+  labels := registeredLabels()
+  fn := func(element *Element) {
+    for _, attr := range element.attributes {
+      if attr.Name.Local == "Label" && labels[attr.Value] {
+        overrideItemsSpec(element, files, attr.Value, spec.Files)
+      }
+    }
+  }
+
+  scanTemplate(element, fn)
+  applySpec(element, spec)
+
+  outpath := filepath.Join(filepath.ToSlash(outdir), projname + ".vcxproj")
+
+  fp_out, err := os.OpenFile(outpath, os.O_CREATE | os.O_TRUNC, 0666)
+  if err != nil {
+    fmt.Println("File opening error occurred while writing project file.")
+    return
+  }
+  doc.Encode(fp_out)
+
+  fp_out.Close()
+
+  exportFilter(files, outpath + ".filters")
+}
+
+// conditionConfig parses a '$(Configuration)|$(Platform)'=='Name|Platform'
+// Condition attribute value into a Config.
+func conditionConfig(condition string) (Config, bool) {
+  const want = "'$(Configuration)|$(Platform)'=='"
+  if !strings.HasPrefix(condition, want) || !strings.HasSuffix(condition, "'") {
+    return Config{}, false
+  }
+
+  inner := condition[len(want) : len(condition)-1]
+  parts := strings.SplitN(inner, "|", 2)
+  if len(parts) != 2 {
+    return Config{}, false
+  }
+
+  return Config{Name: parts[0], Platform: parts[1]}, true
+}
+
+// applySpec walks every element in the template looking for a Condition
+// attribute naming a Config present in spec.Configs, and overwrites its
+// children from the matching ConfigSpec.
+func applySpec(element *Element, spec ProjectSpec) {
+  for _, attr := range element.attributes {
+    if attr.Name.Local != "Condition" {
+      continue
+    }
+
+    if cfg, ok := conditionConfig(attr.Value); ok {
+      if cs, ok := spec.Configs[cfg]; ok {
+        applyConfigSpec(element, cs)
+      }
+    }
+  }
+
+  for _, child := range element.children {
+    if c, ok := child.(*Element); ok {
+      applySpec(c, spec)
+    }
+  }
+}
+
+// applyConfigSpec applies a ConfigSpec to the PropertyGroup or
+// ItemDefinitionGroup it was matched against. OutDir/IntDir are genuinely
+// PropertyGroup-level properties and are written as direct children, but
+// the compiler/linker settings belong to the vcxproj schema's ClCompile
+// and Link sub-elements of an ItemDefinitionGroup, not the group itself —
+// MSBuild silently ignores e.g. a bare <PreprocessorDefinitions> hung
+// directly off <ItemDefinitionGroup>.
+func applyConfigSpec(element *Element, cs ConfigSpec) {
+  switch element.name.Local {
+  case "PropertyGroup":
+    if cs.OutDir != "" {
+      setChildText(element, "OutDir", cs.OutDir)
+    }
+    if cs.IntDir != "" {
+      setChildText(element, "IntDir", cs.IntDir)
+    }
+
+  case "ItemDefinitionGroup":
+    if len(cs.Defines) > 0 || len(cs.IncludeDirs) > 0 || cs.Optimization != "" || cs.LanguageStandard != "" || cs.RuntimeLibrary != "" {
+      clCompile := getOrAddChild(element, "ClCompile")
+      if len(cs.Defines) > 0 {
+        setChildText(clCompile, "PreprocessorDefinitions", strings.Join(cs.Defines, ";") + ";%(PreprocessorDefinitions)")
+      }
+      if len(cs.IncludeDirs) > 0 {
+        setChildText(clCompile, "AdditionalIncludeDirectories", strings.Join(cs.IncludeDirs, ";") + ";%(AdditionalIncludeDirectories)")
+      }
+      if cs.Optimization != "" {
+        setChildText(clCompile, "Optimization", cs.Optimization)
+      }
+      if cs.LanguageStandard != "" {
+        setChildText(clCompile, "LanguageStandard", cs.LanguageStandard)
+      }
+      if cs.RuntimeLibrary != "" {
+        setChildText(clCompile, "RuntimeLibrary", cs.RuntimeLibrary)
+      }
+    }
+
+    if len(cs.LibDirs) > 0 || len(cs.Libs) > 0 {
+      link := getOrAddChild(element, "Link")
+      if len(cs.LibDirs) > 0 {
+        setChildText(link, "AdditionalLibraryDirectories", strings.Join(cs.LibDirs, ";") + ";%(AdditionalLibraryDirectories)")
+      }
+      if len(cs.Libs) > 0 {
+        setChildText(link, "AdditionalDependencies", strings.Join(cs.Libs, ";") + ";%(AdditionalDependencies)")
+      }
+    }
+  }
+}
+
+// getOrAddChild returns the first existing child of element named name,
+// adding one if it isn't present yet.
+func getOrAddChild(element *Element, name string) *Element {
+  for _, child := range element.children {
+    if c, ok := child.(*Element); ok && c.name.Local == name {
+      return c
+    }
+  }
+  return element.AddChild(name)
+}
+
+// setChildText overwrites the CharData of the named child of element,
+// creating the child if it doesn't already exist.
+func setChildText(element *Element, name, value string) {
+  for _, child := range element.children {
+    c, ok := child.(*Element)
+    if !ok || c.name.Local != name {
+      continue
+    }
+    c.children = c.children[:0]
+    c.AddCharData(value)
+    return
+  }
+
+  child := element.AddChild(name)
+  child.AddCharData(value)
+}
+
+// overrideItemsSpec is overrideItems plus per-file overrides: files
+// excluded from a configuration get an ExcludedFromBuild child per
+// excluded Config, and a file with ForcePrecompiledHeader gets a
+// PrecompiledHeader override of "Create".
+func overrideItemsSpec(element *Element, files []string, label string, fileSpecs map[string]FileSpec) {
+  element.attributes = element.attributes[:0]
+
+  for _, file := range files {
+    file = filepath.FromSlash(file)
+    entry, ok := itemTypeFor(file)
+    if !ok || entry.label != label {
+      continue
+    }
+
+    child := element.AddChild(entry.itemName)
+    child.AddAttribute("Include", file)
+
+    if fs, ok := fileSpecs[file]; ok {
+      for _, cfg := range fs.ExcludedConfigs {
+        excl := child.AddChild("ExcludedFromBuild")
+        excl.AddAttribute("Condition", "'$(Configuration)|$(Platform)'=='" + cfg.String() + "'")
+        excl.AddCharData("true")
+      }
+
+      if fs.ForcePrecompiledHeader {
+        pch := child.AddChild("PrecompiledHeader")
+        pch.AddCharData("Create")
+      }
+    }
+
+    element.AddCharData("\n")
+  }
+}
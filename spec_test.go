@@ -0,0 +1,93 @@
+package msbuild
+
+import (
+  "strings"
+  "testing"
+)
+
+const specTestTemplate = `<Project xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup Label="Configuration" Condition="'$(Configuration)|$(Platform)'=='Debug|Win32'"></PropertyGroup>
+  <ItemDefinitionGroup Condition="'$(Configuration)|$(Platform)'=='Debug|Win32'"></ItemDefinitionGroup>
+</Project>`
+
+func findChild(element *Element, name string) *Element {
+  for _, child := range element.children {
+    if c, ok := child.(*Element); ok && c.name.Local == name {
+      return c
+    }
+  }
+  return nil
+}
+
+func childText(element *Element, name string) string {
+  child := findChild(element, name)
+  if child == nil {
+    return ""
+  }
+  for _, token := range child.children {
+    if cd, ok := token.(CharData); ok {
+      return string(cd)
+    }
+  }
+  return ""
+}
+
+func TestApplyConfigSpecNestsCompilerAndLinkerSettings(t *testing.T) {
+  doc, err := DecodeDocument(strings.NewReader(specTestTemplate))
+  if err != nil {
+    t.Fatalf("DecodeDocument: %v", err)
+  }
+
+  spec := ProjectSpec{
+    Configs: map[Config]ConfigSpec{
+      {Name: "Debug", Platform: "Win32"}: {
+        Defines:     []string{"FOO"},
+        IncludeDirs: []string{"include"},
+        LibDirs:     []string{"lib"},
+        Libs:        []string{"foo.lib"},
+        OutDir:      `bin\`,
+      },
+    },
+  }
+
+  applySpec(doc.Root, spec)
+
+  idg := findChild(doc.Root, "ItemDefinitionGroup")
+  if idg == nil {
+    t.Fatal("ItemDefinitionGroup not found")
+  }
+
+  clCompile := findChild(idg, "ClCompile")
+  if clCompile == nil {
+    t.Fatal("expected a ClCompile child under ItemDefinitionGroup, got none")
+  }
+  if got := childText(clCompile, "PreprocessorDefinitions"); !strings.Contains(got, "FOO") {
+    t.Errorf("PreprocessorDefinitions = %q, want it to contain FOO", got)
+  }
+  if got := childText(clCompile, "AdditionalIncludeDirectories"); !strings.Contains(got, "include") {
+    t.Errorf("AdditionalIncludeDirectories = %q, want it to contain include", got)
+  }
+
+  link := findChild(idg, "Link")
+  if link == nil {
+    t.Fatal("expected a Link child under ItemDefinitionGroup, got none")
+  }
+  if got := childText(link, "AdditionalLibraryDirectories"); !strings.Contains(got, "lib") {
+    t.Errorf("AdditionalLibraryDirectories = %q, want it to contain lib", got)
+  }
+  if got := childText(link, "AdditionalDependencies"); !strings.Contains(got, "foo.lib") {
+    t.Errorf("AdditionalDependencies = %q, want it to contain foo.lib", got)
+  }
+
+  if childText(idg, "PreprocessorDefinitions") != "" {
+    t.Error("PreprocessorDefinitions must not be a direct child of ItemDefinitionGroup")
+  }
+
+  pg := findChild(doc.Root, "PropertyGroup")
+  if pg == nil {
+    t.Fatal("PropertyGroup not found")
+  }
+  if got := childText(pg, "OutDir"); got != `bin\` {
+    t.Errorf("OutDir = %q, want bin\\", got)
+  }
+}
@@ -0,0 +1,190 @@
+package msbuild
+
+import (
+  "io/fs"
+  "path/filepath"
+  "strings"
+  "time"
+  "github.com/fsnotify/fsnotify"
+)
+
+// WatchOptions controls the debounce behaviour of Watch.
+type WatchOptions struct {
+  Delay time.Duration // debounce window before re-exporting, defaults to 100ms
+}
+
+// Watch expands globs (e.g. "**/*.cpp", "**/*.h") into a file list, writes
+// an initial project via ExportProject, then watches the directories
+// covered by globs with fsnotify and re-runs ExportProject (debounced by
+// opts.Delay) whenever a matching file is created, renamed or removed. It
+// blocks until the fsnotify watcher reports an error, which it returns, so
+// callers typically run it in its own goroutine.
+func Watch(globs []string, outdir, projname string, opts WatchOptions) error {
+  if opts.Delay == 0 {
+    opts.Delay = 100 * time.Millisecond
+  }
+
+  files, err := expandGlobs(globs)
+  if err != nil {
+    return err
+  }
+
+  ExportProject(files, outdir, projname)
+
+  watcher, err := fsnotify.NewWatcher()
+  if err != nil {
+    return err
+  }
+  defer watcher.Close()
+
+  for _, dir := range watchedDirs(globs) {
+    if err := watcher.Add(dir); err != nil {
+      return err
+    }
+  }
+
+  var timer *time.Timer
+
+  for {
+    select {
+    case event, ok := <-watcher.Events:
+      if !ok {
+        return nil
+      }
+
+      if !matchesGlobs(event.Name, globs) {
+        continue
+      }
+
+      if event.Op & (fsnotify.Create | fsnotify.Rename | fsnotify.Remove) == 0 {
+        continue
+      }
+
+      if timer != nil {
+        timer.Stop()
+      }
+      timer = time.AfterFunc(opts.Delay, func() {
+        files, err := expandGlobs(globs)
+        if err != nil {
+          return
+        }
+        ExportProject(files, outdir, projname)
+      })
+
+    case err, ok := <-watcher.Errors:
+      if !ok {
+        return nil
+      }
+      return err
+    }
+  }
+}
+
+// globBase splits a "**"-pattern like "src/**/*.cpp" into the directory to
+// walk ("src") and the filename pattern to match against every file found
+// under it ("*.cpp"). recursive is false for a plain pattern, which
+// filepath.Glob already handles correctly on its own.
+func globBase(pattern string) (dir, namePattern string, recursive bool) {
+  pattern = filepath.ToSlash(pattern)
+  idx := strings.Index(pattern, "**")
+  if idx < 0 {
+    return "", "", false
+  }
+
+  dir = filepath.Dir(pattern[:idx])
+  namePattern = strings.TrimPrefix(pattern[idx+2:], "/")
+  return dir, namePattern, true
+}
+
+// expandGlobs expands every pattern in globs into the files it covers. A
+// "**" pattern is walked recursively with filepath.WalkDir, since
+// filepath.Glob has no notion of "**" and only ever matches one path
+// segment per wildcard; plain patterns are left to filepath.Glob.
+func expandGlobs(globs []string) ([]string, error) {
+  var files []string
+  for _, pattern := range globs {
+    dir, namePattern, recursive := globBase(pattern)
+    if !recursive {
+      matches, err := filepath.Glob(pattern)
+      if err != nil {
+        return nil, err
+      }
+      files = append(files, matches...)
+      continue
+    }
+
+    err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+      if err != nil {
+        return err
+      }
+      if d.IsDir() {
+        return nil
+      }
+      if ok, _ := filepath.Match(namePattern, filepath.Base(path)); ok {
+        files = append(files, path)
+      }
+      return nil
+    })
+    if err != nil {
+      return nil, err
+    }
+  }
+  return files, nil
+}
+
+// watchedDirs returns every directory Watch needs to register with
+// fsnotify (which only watches the directories it's explicitly told
+// about, not their subdirectories): for a "**" pattern that's every
+// directory under its base, walked recursively; for a plain pattern it's
+// just filepath.Dir(pattern).
+func watchedDirs(globs []string) []string {
+  seen := make(map[string]bool)
+  var dirs []string
+  add := func(dir string) {
+    if !seen[dir] {
+      seen[dir] = true
+      dirs = append(dirs, dir)
+    }
+  }
+
+  for _, pattern := range globs {
+    dir, _, recursive := globBase(pattern)
+    if !recursive {
+      add(filepath.Dir(pattern))
+      continue
+    }
+
+    filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+      if err != nil || !d.IsDir() {
+        return nil
+      }
+      add(path)
+      return nil
+    })
+  }
+
+  return dirs
+}
+
+// matchesGlobs reports whether path matches any of the given glob
+// patterns, including "**" patterns.
+func matchesGlobs(path string, globs []string) bool {
+  for _, pattern := range globs {
+    dir, namePattern, recursive := globBase(pattern)
+    if !recursive {
+      if ok, err := filepath.Match(pattern, path); err == nil && ok {
+        return true
+      }
+      continue
+    }
+
+    rel, err := filepath.Rel(dir, path)
+    if err != nil || strings.HasPrefix(rel, "..") {
+      continue
+    }
+    if ok, _ := filepath.Match(namePattern, filepath.Base(path)); ok {
+      return true
+    }
+  }
+  return false
+}
@@ -0,0 +1,92 @@
+package msbuild
+
+import (
+  "os"
+  "path/filepath"
+  "sort"
+  "testing"
+)
+
+func writeTestFile(t *testing.T, path string) {
+  t.Helper()
+  if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+    t.Fatal(err)
+  }
+  if err := os.WriteFile(path, nil, 0666); err != nil {
+    t.Fatal(err)
+  }
+}
+
+func TestExpandGlobsRecursesNestedDirectories(t *testing.T) {
+  root := t.TempDir()
+
+  writeTestFile(t, filepath.Join(root, "main.cpp"))
+  writeTestFile(t, filepath.Join(root, "a", "x.cpp"))
+  writeTestFile(t, filepath.Join(root, "a", "b", "y.cpp"))
+  writeTestFile(t, filepath.Join(root, "a", "b", "z.h"))
+
+  files, err := expandGlobs([]string{
+    filepath.Join(root, "**", "*.cpp"),
+  })
+  if err != nil {
+    t.Fatalf("expandGlobs: %v", err)
+  }
+
+  got := make([]string, len(files))
+  copy(got, files)
+  sort.Strings(got)
+
+  want := []string{
+    filepath.Join(root, "a", "b", "y.cpp"),
+    filepath.Join(root, "a", "x.cpp"),
+    filepath.Join(root, "main.cpp"),
+  }
+  sort.Strings(want)
+
+  if len(got) != len(want) {
+    t.Fatalf("got %v, want %v", got, want)
+  }
+  for i := range got {
+    if got[i] != want[i] {
+      t.Errorf("got[%d] = %q, want %q", i, got[i], want[i])
+    }
+  }
+}
+
+func TestMatchesGlobsNestedFile(t *testing.T) {
+  root := t.TempDir()
+  nested := filepath.Join(root, "a", "b", "y.cpp")
+  writeTestFile(t, nested)
+
+  globs := []string{filepath.Join(root, "**", "*.cpp")}
+
+  if !matchesGlobs(nested, globs) {
+    t.Errorf("matchesGlobs(%q, %v) = false, want true", nested, globs)
+  }
+  if matchesGlobs(filepath.Join(root, "a", "b", "z.h"), globs) {
+    t.Errorf("matchesGlobs should not match a non-.cpp file against a *.cpp pattern")
+  }
+}
+
+func TestWatchedDirsCoversNestedDirectories(t *testing.T) {
+  root := t.TempDir()
+  writeTestFile(t, filepath.Join(root, "a", "b", "y.cpp"))
+
+  dirs := watchedDirs([]string{filepath.Join(root, "**", "*.cpp")})
+
+  want := map[string]bool{
+    root: false,
+    filepath.Join(root, "a"):    false,
+    filepath.Join(root, "a", "b"): false,
+  }
+  for _, d := range dirs {
+    if _, ok := want[d]; ok {
+      want[d] = true
+    }
+  }
+  for dir, found := range want {
+    if !found {
+      t.Errorf("watchedDirs missing %q, got %v", dir, dirs)
+    }
+  }
+}